@@ -0,0 +1,48 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package anthropic
+
+import (
+	"fmt"
+
+	anthropicSDK "github.com/anthropics/anthropic-sdk-go"
+	"github.com/firebase/genkit/go/plugins/internal/configschema"
+)
+
+func init() {
+	configschema.Register("anthropic", DecodeConfig)
+}
+
+// DecodeConfig validates a request's raw config map and decodes it into
+// anthropicSDK.MessageNewParams, defaulting MaxTokens to 4096 since
+// Anthropic requires it. Registered with configschema at init time so
+// callers like the conform native executor never need to know Anthropic's
+// config shape themselves.
+func DecodeConfig(raw map[string]any) (any, error) {
+	cfg := &anthropicSDK.MessageNewParams{MaxTokens: 4096}
+	if raw == nil {
+		return cfg, nil
+	}
+	if v, ok := raw["maxOutputTokens"]; ok {
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("config.maxOutputTokens must be a number, got %T", v)
+		}
+		cfg.MaxTokens = int64(f)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package anthropic
+
+import (
+	"testing"
+
+	anthropicSDK "github.com/anthropics/anthropic-sdk-go"
+)
+
+func TestDecodeConfigDefaults(t *testing.T) {
+	cfg, err := DecodeConfig(nil)
+	if err != nil {
+		t.Fatalf("DecodeConfig(nil) returned error: %v", err)
+	}
+	params, ok := cfg.(*anthropicSDK.MessageNewParams)
+	if !ok {
+		t.Fatalf("DecodeConfig(nil) returned %T, want *anthropicSDK.MessageNewParams", cfg)
+	}
+	if params.MaxTokens != 4096 {
+		t.Errorf("MaxTokens = %d, want default 4096", params.MaxTokens)
+	}
+}
+
+func TestDecodeConfigMaxOutputTokens(t *testing.T) {
+	cfg, err := DecodeConfig(map[string]any{"maxOutputTokens": float64(512)})
+	if err != nil {
+		t.Fatalf("DecodeConfig returned error: %v", err)
+	}
+	params := cfg.(*anthropicSDK.MessageNewParams)
+	if params.MaxTokens != 512 {
+		t.Errorf("MaxTokens = %d, want 512", params.MaxTokens)
+	}
+}
+
+func TestDecodeConfigInvalidMaxOutputTokens(t *testing.T) {
+	_, err := DecodeConfig(map[string]any{"maxOutputTokens": "not a number"})
+	if err == nil {
+		t.Fatal("DecodeConfig with non-numeric maxOutputTokens: want error, got nil")
+	}
+}
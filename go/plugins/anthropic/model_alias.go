@@ -0,0 +1,44 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package anthropic
+
+import "github.com/firebase/genkit/go/plugins/internal/modelalias"
+
+// availableModels are the dated Claude releases this plugin knows about.
+// The registered Resolver resolves aliases (e.g. "claude-opus-4-5") against
+// this list.
+//
+// Only anthropic is wired through modelalias so far: googlegenai, vertexai,
+// and ollama live outside this tree (they're imported from the upstream
+// genkit module, not vendored here) and haven't been migrated onto the
+// shared resolver.
+var availableModels = []string{
+	"claude-opus-4-6",
+	"claude-opus-4-5-20251101",
+	"claude-opus-4-1-20250805",
+	"claude-opus-4-20250514",
+	"claude-sonnet-4-5-20250929",
+	"claude-sonnet-4-20250514",
+	"claude-haiku-4-5-20251001",
+}
+
+func init() {
+	r := modelalias.NewResolver(availableModels)
+	r.SetChannel("latest", "claude-opus-4-6")
+	r.SetChannel("stable", "claude-opus-4-5-20251101")
+	modelalias.Register("anthropic", r)
+}
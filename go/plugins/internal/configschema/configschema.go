@@ -0,0 +1,50 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package configschema lets a Genkit Go plugin own how its model config is
+// validated and decoded, and register that behavior once at init time,
+// instead of callers (like the conform native executor) hardcoding a branch
+// per plugin.
+package configschema
+
+import "sync"
+
+// Decoder validates a request's raw "config" map and decodes it into the
+// native config type a plugin's models expect, applying any required
+// defaults. A nil, nil return means there's nothing to pass to
+// ai.WithConfig.
+type Decoder func(raw map[string]any) (any, error)
+
+var (
+	mu       sync.Mutex
+	registry = map[string]Decoder{}
+)
+
+// Register associates plugin (the name conform knows it by, e.g.
+// "anthropic") with its Decoder. Plugins call this from an init() func.
+func Register(plugin string, decode Decoder) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[plugin] = decode
+}
+
+// Lookup returns the Decoder registered for plugin, if any.
+func Lookup(plugin string) (Decoder, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	decode, ok := registry[plugin]
+	return decode, ok
+}
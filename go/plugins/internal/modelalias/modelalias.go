@@ -0,0 +1,166 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package modelalias resolves a user- or plugin-supplied model name to a
+// concrete model ID shared across Genkit's Go plugins.
+//
+// Three lookup mechanisms are supported, checked in this order:
+//
+//  1. A process-wide pin map loaded from $GENKIT_MODEL_PINS, letting users
+//     lock an alias to a specific dated release for reproducible evals.
+//  2. Named channels ("latest", "stable", "preview", ...) that a plugin
+//     registers from its own model list at registration time.
+//  3. Dated-suffix family aliases, e.g. "claude-opus-4-5" resolving to the
+//     most recent "claude-opus-4-5-<YYYYMMDD>" release in the plugin's
+//     list of available models.
+package modelalias
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Resolution describes how a requested model name was resolved: the
+// concrete canonical ID Genkit should call, the chain of names that led to
+// it (requested name first, canonical ID last), and which channel (if any)
+// was involved.
+type Resolution struct {
+	Canonical  string
+	AliasChain []string
+	Channel    string
+}
+
+// Resolver resolves model names for a single plugin's list of available
+// models and registered channels. The pin map is process-wide and shared
+// across all Resolvers.
+type Resolver struct {
+	available []string
+	channels  map[string]string
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]*Resolver{}
+)
+
+// Register makes r reachable by plugin name (e.g. "anthropic") for callers
+// that only know the plugin at runtime, such as the conform native
+// executor logging which alias a test actually hit. Plugins call this from
+// an init() func, right after building their Resolver.
+func Register(plugin string, r *Resolver) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[plugin] = r
+}
+
+// Lookup returns the Resolver registered for plugin, if any.
+func Lookup(plugin string) (*Resolver, bool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	r, ok := registry[plugin]
+	return r, ok
+}
+
+// NewResolver creates a Resolver over a plugin's list of concrete model IDs.
+func NewResolver(available []string) *Resolver {
+	return &Resolver{available: available, channels: map[string]string{}}
+}
+
+// SetChannel registers a named channel (e.g. "latest") as an alias for a
+// concrete model ID. Plugins call this once per channel they support when
+// they register their models.
+func (r *Resolver) SetChannel(channel, modelID string) {
+	r.channels[channel] = modelID
+}
+
+// Resolve maps a requested model name to a Resolution. found is false if
+// none of the pin map, the registered channels, or the dated-suffix alias
+// convention resolve requested.
+func (r *Resolver) Resolve(requested string) (Resolution, bool) {
+	if pinned, ok := modelPins()[requested]; ok {
+		return Resolution{Canonical: pinned, AliasChain: []string{requested, pinned}, Channel: "pin"}, true
+	}
+
+	if canonical, ok := r.channels[requested]; ok {
+		return Resolution{Canonical: canonical, AliasChain: []string{requested, canonical}, Channel: requested}, true
+	}
+
+	canonical, ok := resolveDatedAlias(requested, r.available)
+	if !ok {
+		return Resolution{}, false
+	}
+	chain := []string{requested}
+	if canonical != requested {
+		chain = append(chain, canonical)
+	}
+	return Resolution{Canonical: canonical, AliasChain: chain}, true
+}
+
+var datedSuffix = regexp.MustCompile(`^[0-9]{8}$`)
+
+// resolveDatedAlias resolves requested against available using the
+// "<family>-<YYYYMMDD>" dated-release convention: an exact match always
+// wins, otherwise the most recent dated release sharing the requested
+// family name is returned.
+func resolveDatedAlias(requested string, available []string) (string, bool) {
+	for _, m := range available {
+		if m == requested {
+			return m, true
+		}
+	}
+
+	var best, bestSuffix string
+	for _, m := range available {
+		suffix := strings.TrimPrefix(m, requested+"-")
+		if suffix == m || !datedSuffix.MatchString(suffix) {
+			continue
+		}
+		if suffix > bestSuffix {
+			best, bestSuffix = m, suffix
+		}
+	}
+	if best == "" {
+		return "", false
+	}
+	return best, true
+}
+
+var (
+	pinsOnce sync.Once
+	pins     map[string]string
+)
+
+// modelPins loads the user-overridable alias -> concrete ID pin map from
+// $GENKIT_MODEL_PINS once per process. An unset or unreadable file yields an
+// empty map, since pinning is optional.
+func modelPins() map[string]string {
+	pinsOnce.Do(func() {
+		pins = map[string]string{}
+		path := os.Getenv("GENKIT_MODEL_PINS")
+		if path == "" {
+			return
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return
+		}
+		_ = json.Unmarshal(data, &pins)
+	})
+	return pins
+}
@@ -14,13 +14,15 @@
 //
 // SPDX-License-Identifier: Apache-2.0
 
-package anthropic
+package modelalias
 
 import (
 	"testing"
 )
 
-func TestResolveModelID(t *testing.T) {
+// Moved from go/plugins/anthropic, which originally owned this table before
+// dated-suffix resolution became a cross-plugin concern.
+func TestResolverResolve(t *testing.T) {
 	availableModels := []string{
 		"claude-opus-4-6",
 		"claude-opus-4-5-20251101",
@@ -52,15 +54,26 @@ func TestResolveModelID(t *testing.T) {
 		{"claude-2", "", false},
 	}
 
+	r := NewResolver(availableModels)
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			got, found := resolveModelID(tt.input, availableModels)
+			res, found := r.Resolve(tt.input)
 			if found != tt.found {
 				t.Errorf("found = %v, want %v", found, tt.found)
 			}
-			if got != tt.expected {
-				t.Errorf("got = %q, want %q", got, tt.expected)
+			if res.Canonical != tt.expected {
+				t.Errorf("got = %q, want %q", res.Canonical, tt.expected)
 			}
 		})
 	}
 }
+
+func TestResolverChannelsAndPins(t *testing.T) {
+	r := NewResolver([]string{"claude-opus-4-5-20251101"})
+	r.SetChannel("latest", "claude-opus-4-5-20251101")
+
+	res, found := r.Resolve("latest")
+	if !found || res.Canonical != "claude-opus-4-5-20251101" || res.Channel != "latest" {
+		t.Errorf("Resolve(%q) = %+v, %v; want channel latest -> claude-opus-4-5-20251101", "latest", res, found)
+	}
+}
@@ -26,6 +26,15 @@
 //  6. Writes one JSON line to stdout with the response.
 //  7. Repeats until stdin closes.
 //
+// Requests and responses are correlated by an "id" field so that the
+// request/response stream can interleave with the tool call/result stream
+// (see toolCallFrame/toolResultFrame below) on the same stdio pipes.
+//
+// --plugin names outside the built-in pluginRegistry are resolved against
+// genkit-plugin-* binaries discovered on $GENKIT_PLUGINS_DIR and $PATH (see
+// discoverExternalPlugins); this process then proxies stdio to the matching
+// binary, which is expected to speak the same protocol.
+//
 // Driven by the Python `conform` tool:
 //
 //	conform check-model --runtime go --runner native
@@ -37,21 +46,29 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
-	anthropicSDK "github.com/anthropics/anthropic-sdk-go"
 	"github.com/firebase/genkit/go/ai"
 	"github.com/firebase/genkit/go/genkit"
 	"github.com/firebase/genkit/go/plugins/anthropic"
 	"github.com/firebase/genkit/go/plugins/googlegenai"
+	"github.com/firebase/genkit/go/plugins/internal/configschema"
+	"github.com/firebase/genkit/go/plugins/internal/modelalias"
 	"github.com/firebase/genkit/go/plugins/ollama"
 )
 
 // pluginInitFunc returns the genkit.GenkitOption to initialize a specific plugin.
 type pluginInitFunc func() genkit.GenkitOption
 
-// pluginRegistry maps conform plugin names to their init functions.
+// pluginRegistry maps conform plugin names to their init functions. Names
+// not found here fall through to external plugin discovery (see
+// discoverExternalPlugins) before the executor gives up.
 var pluginRegistry = map[string]pluginInitFunc{
 	"google-genai": func() genkit.GenkitOption { return genkit.WithPlugins(&googlegenai.GoogleAI{}) },
 	"vertex-ai":    func() genkit.GenkitOption { return genkit.WithPlugins(&googlegenai.VertexAI{}) },
@@ -63,18 +80,327 @@ var pluginRegistry = map[string]pluginInitFunc{
 	},
 }
 
+// decodeGenericConfig passes the raw config map straight through, letting
+// the model's own registered schema validate it inside ai.WithConfig. It's
+// the fallback for any plugin that hasn't registered a configschema.Decoder
+// of its own - today that's every plugin except anthropic, which is the
+// only one that's actually shipped one (see anthropic/config.go). Googlegenai,
+// vertexai, and ollama fall back to this exactly as they did before
+// configschema existed; nothing plugin-specific has been written for them.
+func decodeGenericConfig(raw map[string]any) (any, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	return raw, nil
+}
+
+// pluginPrefix returns the plugin name portion of a model ID, e.g.
+// "anthropic" for "anthropic/claude-opus-4-5".
+func pluginPrefix(model string) string {
+	name, _, _ := strings.Cut(model, "/")
+	return name
+}
+
+// defaultPluginsDir is where external plugin binaries are discovered when
+// $GENKIT_PLUGINS_DIR is unset.
+const defaultPluginsDir = "./plugins"
+
+// pluginManifestRequest is the well-known frame every external plugin
+// binary must answer, describing its supported models, config schema, and
+// capabilities.
+type pluginManifestRequest struct {
+	Manifest bool `json:"manifest"`
+}
+
+var (
+	manifestCacheMu sync.Mutex
+	manifestCache   = map[string]map[string]any{}
+)
+
+// discoverExternalPlugins scans $GENKIT_PLUGINS_DIR (or defaultPluginsDir)
+// and $PATH for executables named genkit-plugin-<name> and returns them as
+// a name -> path map. It does not start any of them; manifests are fetched
+// lazily by manifestFor the first time a plugin is actually proxied to.
+func discoverExternalPlugins() map[string]string {
+	found := map[string]string{}
+
+	dir := os.Getenv("GENKIT_PLUGINS_DIR")
+	if dir == "" {
+		dir = defaultPluginsDir
+	}
+	addGenkitPluginBinaries(dir, found)
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		addGenkitPluginBinaries(dir, found)
+	}
+
+	return found
+}
+
+// addGenkitPluginBinaries adds every genkit-plugin-<name> executable found
+// directly inside dir to found, keyed by <name>. Existing entries win, so
+// earlier directories (GENKIT_PLUGINS_DIR, then PATH in order) take
+// precedence.
+func addGenkitPluginBinaries(dir string, found map[string]string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := strings.TrimPrefix(e.Name(), "genkit-plugin-")
+		if name == e.Name() {
+			continue // doesn't match the genkit-plugin-* convention
+		}
+		if _, exists := found[name]; !exists {
+			found[name] = filepath.Join(dir, e.Name())
+		}
+	}
+}
+
+// cachedManifest returns the manifest cached for plugin, if one has been
+// fetched yet, without starting anything itself.
+func cachedManifest(name string) (map[string]any, bool) {
+	manifestCacheMu.Lock()
+	defer manifestCacheMu.Unlock()
+	m, ok := manifestCache[name]
+	return m, ok
+}
+
+// cacheManifest records m as the manifest for plugin name.
+func cacheManifest(name string, m map[string]any) {
+	manifestCacheMu.Lock()
+	defer manifestCacheMu.Unlock()
+	manifestCache[name] = m
+}
+
+// manifestFor starts path just long enough to answer a manifest request,
+// caches the reply, and stops the process. Used by availablePlugins/
+// logPluginManifests to describe a plugin without proxying a real session
+// to it; runExternalPlugin fetches the manifest itself over the same
+// process it proxies to, rather than calling this.
+func manifestFor(name, path string) map[string]any {
+	if m, ok := cachedManifest(name); ok {
+		return m
+	}
+
+	cmd := exec.Command(path)
+	stdin, err := cmd.StdinPipe()
+	if err == nil {
+		var stdout io.ReadCloser
+		stdout, err = cmd.StdoutPipe()
+		if err == nil {
+			if err = cmd.Start(); err == nil {
+				scanner := bufio.NewScanner(stdout)
+				scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+				m, mErr := requestManifest(stdin, scanner)
+				cmd.Process.Kill()
+				cmd.Wait()
+				if mErr == nil {
+					cacheManifest(name, m)
+					return m
+				}
+				err = mErr
+			}
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "warning: failed to fetch manifest for plugin %q: %v\n", name, err)
+	m := map[string]any{}
+	cacheManifest(name, m)
+	return m
+}
+
+// requestManifest sends a pluginManifestRequest on stdin and reads scanner
+// until it finds the plugin's manifest reply, skipping any leading frames
+// that aren't one (e.g. {"ready": true}).
+func requestManifest(stdin io.Writer, scanner *bufio.Scanner) (map[string]any, error) {
+	req, _ := json.Marshal(pluginManifestRequest{Manifest: true})
+	if _, err := fmt.Fprintf(stdin, "%s\n", req); err != nil {
+		return nil, err
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var frame map[string]any
+		if err := json.Unmarshal([]byte(line), &frame); err != nil {
+			continue
+		}
+		if _, ok := frame["manifest"]; ok {
+			return frame, nil
+		}
+	}
+	return nil, fmt.Errorf("plugin did not answer the manifest request")
+}
+
+// runExternalPlugin starts the plugin binary at path exactly once: it reads
+// the manifest reply over the same pipes, caches it, then proxies the rest
+// of this process's stdio to/from that one child so conform can't tell an
+// external plugin apart from a built-in one.
+func runExternalPlugin(name, path string) error {
+	cmd := exec.Command(path)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	if m, err := requestManifest(stdin, scanner); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to fetch manifest for plugin %q: %v\n", name, err)
+		cacheManifest(name, map[string]any{})
+	} else {
+		cacheManifest(name, m)
+	}
+
+	// Forward conform's stdin to the child, and whatever the child writes
+	// after its manifest reply back to conform. Close the child's stdin once
+	// conform's own stdin hits EOF, so its readStdin loop sees EOF too
+	// instead of leaving cmd.Wait below blocked forever on shutdown.
+	go func() {
+		io.Copy(stdin, os.Stdin)
+		stdin.Close()
+	}()
+	for scanner.Scan() {
+		fmt.Fprintf(os.Stdout, "%s\n", scanner.Bytes())
+	}
+
+	return cmd.Wait()
+}
+
+// logPluginManifests prints each external plugin's cached capabilities to
+// stderr, fetching the manifest first if it hasn't been already. Called
+// alongside availablePlugins() so a user picking --plugin sees more than
+// bare names.
+func logPluginManifests() {
+	for name, path := range discoverExternalPlugins() {
+		m := manifestFor(name, path)
+		if models, ok := m["models"]; ok {
+			fmt.Fprintf(os.Stderr, "  %s: models=%v\n", name, models)
+		}
+	}
+}
+
 // nativeRequest is the JSON structure received from conform on stdin.
+//
+// ID correlates this request with its nativeResponse, and with any
+// toolCallFrame/toolResultFrame exchanged while it's in flight, so that all
+// three frame kinds can share the same stdio pipes.
+//
+// Session, if set, groups this request with prior requests carrying the
+// same ID into one multi-turn conversation (see sessionState).
 type nativeRequest struct {
-	Model  string         `json:"model"`
-	Input  map[string]any `json:"input"`
-	Stream bool           `json:"stream"`
+	ID      string         `json:"id,omitempty"`
+	Model   string         `json:"model"`
+	Input   map[string]any `json:"input"`
+	Stream  bool           `json:"stream"`
+	Session string         `json:"session,omitempty"`
 }
 
 // nativeResponse is the JSON structure sent back to conform on stdout.
+//
+// CumulativeInputTokens and CumulativeOutputTokens are only populated when
+// the request carried a Session, and report usage across every turn of that
+// session so far.
 type nativeResponse struct {
-	Response map[string]any   `json:"response"`
-	Chunks   []map[string]any `json:"chunks"`
-	Error    string           `json:"error,omitempty"`
+	ID                     string           `json:"id,omitempty"`
+	Response               map[string]any   `json:"response"`
+	Chunks                 []map[string]any `json:"chunks"`
+	Error                  string           `json:"error,omitempty"`
+	Code                   string           `json:"code,omitempty"`
+	CumulativeInputTokens  int              `json:"cumulativeInputTokens,omitempty"`
+	CumulativeOutputTokens int              `json:"cumulativeOutputTokens,omitempty"`
+}
+
+// sessionControlFrame requests explicit lifecycle control over a session,
+// independent of any model turn.
+type sessionControlFrame struct {
+	ID             string                `json:"id,omitempty"`
+	SessionControl sessionControlPayload `json:"sessionControl"`
+}
+
+type sessionControlPayload struct {
+	ID     string `json:"id"`
+	Action string `json:"action"` // "reset" or "close"
+}
+
+// sessionState is the per-session conversation history and usage totals
+// that handleRequest prepends to and accumulates across turns sharing a
+// Session ID.
+type sessionState struct {
+	history                []*ai.Message
+	cumulativeInputTokens  int
+	cumulativeOutputTokens int
+}
+
+var (
+	sessionsMu sync.Mutex
+	sessions   = map[string]*sessionState{}
+)
+
+// sessionFor returns the sessionState for id, creating an empty one on
+// first use.
+func sessionFor(id string) *sessionState {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	s, ok := sessions[id]
+	if !ok {
+		s = &sessionState{}
+		sessions[id] = s
+	}
+	return s
+}
+
+// resetSession drops a session's history and usage totals so the next
+// request carrying id starts a fresh conversation. The protocol's "close"
+// action maps here too: conform has no notion of a session outliving the
+// process, so there's nothing left for close to release beyond what reset
+// already clears, but both spellings are accepted since conform's spec
+// promises them as separate actions.
+func resetSession(id string) {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	delete(sessions, id)
+}
+
+// toolCallFrame is written to stdout whenever Genkit's tool loop wants to
+// invoke a tool. conform answers it with a toolResultFrame carrying the same
+// ID and Ref on stdin.
+type toolCallFrame struct {
+	ID       string          `json:"id"`
+	ToolCall toolCallPayload `json:"toolCall"`
+}
+
+type toolCallPayload struct {
+	Ref   string         `json:"ref"`
+	Name  string         `json:"name"`
+	Input map[string]any `json:"input"`
+}
+
+// toolResultFrame is read from stdin in response to a toolCallFrame.
+type toolResultFrame struct {
+	ID         string            `json:"id"`
+	ToolResult toolResultPayload `json:"toolResult"`
+}
+
+type toolResultPayload struct {
+	Ref    string `json:"ref"`
+	Output any    `json:"output"`
 }
 
 // buildMessages converts raw YAML/JSON messages into Genkit messages.
@@ -117,9 +443,32 @@ func buildMessages(raw []any) []*ai.Message {
 // The registry doesn't allow defining the same action name twice.
 var toolCache = map[string]ai.ToolRef{}
 
-// buildTools creates placeholder tools from raw tool definitions.
-// Tools are defined once and cached; subsequent requests with the same
-// tool name reuse the existing registration.
+// currentRequestID is the ID of the nativeRequest currently being handled.
+// Requests are processed one at a time (see main), so a single package-level
+// value is enough to stamp the toolCallFrames a tool handler emits while
+// Genkit's tool loop is running.
+var currentRequestID string
+
+// pendingTools tracks in-flight tool calls for the request currently being
+// handled, keyed by ai.ToolRequest.Ref. readStdin delivers toolResultFrames
+// here as they arrive; tool handlers block on the channel until their result
+// shows up.
+var (
+	pendingToolsMu sync.Mutex
+	pendingTools   = map[string]chan toolResultPayload{}
+)
+
+// stdinClosed is closed by readStdin once stdin is exhausted, letting any
+// tool handler still waiting on a toolResultFrame that will now never
+// arrive give up instead of blocking forever.
+var stdinClosed = make(chan struct{})
+
+// buildTools creates real callback-backed tools from raw tool definitions.
+// Invoking a tool writes a toolCallFrame to stdout and waits for the
+// matching toolResultFrame on stdin, giving up with an error if stdin
+// closes first instead of blocking forever. Tools are defined once and
+// cached; subsequent requests with the same tool name reuse the existing
+// registration.
 func buildTools(g *genkit.Genkit, raw []any) []ai.ToolRef {
 	var tools []ai.ToolRef
 	for _, t := range raw {
@@ -139,16 +488,34 @@ func buildTools(g *genkit.Genkit, raw []any) []ai.ToolRef {
 
 		description, _ := tmap["description"].(string)
 
-		type ToolInput struct {
-			City string `json:"city,omitempty"`
-		}
-
 		tool := genkit.DefineTool(
 			g,
 			name,
 			description,
-			func(ctx *ai.ToolContext, input *ToolInput) (string, error) {
-				return "21C", nil
+			func(ctx *ai.ToolContext, input map[string]any) (any, error) {
+				ref := ctx.ToolRequest.Ref
+
+				ch := make(chan toolResultPayload, 1)
+				pendingToolsMu.Lock()
+				pendingTools[ref] = ch
+				pendingToolsMu.Unlock()
+				defer func() {
+					pendingToolsMu.Lock()
+					delete(pendingTools, ref)
+					pendingToolsMu.Unlock()
+				}()
+
+				writeFrame(toolCallFrame{
+					ID:       currentRequestID,
+					ToolCall: toolCallPayload{Ref: ref, Name: name, Input: input},
+				})
+
+				select {
+				case result := <-ch:
+					return result.Output, nil
+				case <-stdinClosed:
+					return nil, fmt.Errorf("conform closed stdin before answering tool call %q (ref %s)", name, ref)
+				}
 			},
 		)
 		toolCache[name] = tool
@@ -236,36 +603,65 @@ func serializeChunk(c *ai.ModelResponseChunk) map[string]any {
 
 // handleRequest processes a single native request and returns the response.
 func handleRequest(ctx context.Context, g *genkit.Genkit, req *nativeRequest) *nativeResponse {
-	// Build generate options.
-	opts := []ai.GenerateOption{
-		ai.WithModelName(req.Model),
-		ai.WithReturnToolRequests(true),
-	}
-
-	// Config â€” plugin-specific handling.
-	// Each plugin validates config against its own schema, so we can't use
-	// a generic GenerationCommonConfig for all plugins.
-	if strings.HasPrefix(req.Model, "anthropic/") {
-		// Anthropic requires MaxTokens.  Use the SDK's native type.
-		cfg := &anthropicSDK.MessageNewParams{MaxTokens: 4096}
-		if cfgRaw, ok := req.Input["config"].(map[string]any); ok {
-			if v, ok := cfgRaw["maxOutputTokens"].(float64); ok {
-				cfg.MaxTokens = int64(v)
+	currentRequestID = req.ID
+
+	var sess *sessionState
+	if req.Session != "" {
+		sess = sessionFor(req.Session)
+	}
+
+	// Resolve the model name through the plugin's registered modelalias
+	// Resolver, if any, and log the Resolution so conform tests can see
+	// which alias/channel each run actually hit.
+	modelName := req.Model
+	if plugin, bare, ok := strings.Cut(req.Model, "/"); ok {
+		if resolver, ok := modelalias.Lookup(plugin); ok {
+			if res, ok := resolver.Resolve(bare); ok {
+				fmt.Fprintf(os.Stderr, "modelalias: plugin=%s requested=%s canonical=%s chain=%v channel=%q\n",
+					plugin, bare, res.Canonical, res.AliasChain, res.Channel)
+				modelName = plugin + "/" + res.Canonical
 			}
 		}
+	}
+
+	// Build generate options. ToolRequests are no longer returned to the
+	// caller: tools are callback-backed (see buildTools), so Genkit's own
+	// tool loop iterates until the model stops requesting tools.
+	opts := []ai.GenerateOption{
+		ai.WithModelName(modelName),
+	}
+
+	// Config. Each plugin registers its own configschema.Decoder at init
+	// time (see e.g. the anthropic package's config.go), so handleRequest
+	// itself carries no plugin-specific knowledge.
+	var cfgRaw map[string]any
+	if v, ok := req.Input["config"].(map[string]any); ok {
+		cfgRaw = v
+	}
+	decode, ok := configschema.Lookup(pluginPrefix(req.Model))
+	if !ok {
+		decode = decodeGenericConfig
+	}
+	cfg, err := decode(cfgRaw)
+	if err != nil {
+		return &nativeResponse{ID: req.ID, Error: err.Error(), Code: "config_invalid"}
+	}
+	if cfg != nil {
 		opts = append(opts, ai.WithConfig(cfg))
-	} else if cfgRaw, ok := req.Input["config"].(map[string]any); ok {
-		// For other plugins, pass the raw map.  The framework will
-		// validate it against the model's registered schema.
-		opts = append(opts, ai.WithConfig(cfgRaw))
 	}
 
-	// Messages.
+	// Messages. A session prepends its prior history - including the
+	// model's own replies - ahead of this turn's new messages.
+	var builtMsgs []*ai.Message
 	if msgs, ok := req.Input["messages"].([]any); ok {
-		builtMsgs := buildMessages(msgs)
-		if len(builtMsgs) > 0 {
-			opts = append(opts, ai.WithMessages(builtMsgs...))
-		}
+		builtMsgs = buildMessages(msgs)
+	}
+	turnMsgs := builtMsgs
+	if sess != nil && len(sess.history) > 0 {
+		turnMsgs = append(append([]*ai.Message{}, sess.history...), builtMsgs...)
+	}
+	if len(turnMsgs) > 0 {
+		opts = append(opts, ai.WithMessages(turnMsgs...))
 	}
 
 	// Tools.
@@ -296,12 +692,14 @@ func handleRequest(ctx context.Context, g *genkit.Genkit, req *nativeRequest) *n
 	resp, err := genkit.Generate(ctx, g, opts...)
 	if err != nil {
 		return &nativeResponse{
+			ID:    req.ID,
 			Error: err.Error(),
 		}
 	}
 
 	if resp == nil {
 		return &nativeResponse{
+			ID:    req.ID,
 			Error: "generate returned nil response",
 		}
 	}
@@ -312,10 +710,26 @@ func handleRequest(ctx context.Context, g *genkit.Genkit, req *nativeRequest) *n
 		chunkMaps = append(chunkMaps, serializeChunk(c))
 	}
 
-	return &nativeResponse{
+	result := &nativeResponse{
+		ID:       req.ID,
 		Response: serializeResponse(resp),
 		Chunks:   chunkMaps,
 	}
+
+	if sess != nil {
+		sess.history = append(sess.history, builtMsgs...)
+		if resp.Message != nil {
+			sess.history = append(sess.history, resp.Message)
+		}
+		if resp.Usage != nil {
+			sess.cumulativeInputTokens += resp.Usage.InputTokens
+			sess.cumulativeOutputTokens += resp.Usage.OutputTokens
+		}
+		result.CumulativeInputTokens = sess.cumulativeInputTokens
+		result.CumulativeOutputTokens = sess.cumulativeOutputTokens
+	}
+
+	return result
 }
 
 func main() {
@@ -325,13 +739,22 @@ func main() {
 	if *pluginName == "" {
 		fmt.Fprintf(os.Stderr, "error: --plugin is required\n")
 		fmt.Fprintf(os.Stderr, "available plugins: %s\n", strings.Join(availablePlugins(), ", "))
+		logPluginManifests()
 		os.Exit(1)
 	}
 
 	initFn, ok := pluginRegistry[*pluginName]
 	if !ok {
+		if path, ok := discoverExternalPlugins()[*pluginName]; ok {
+			if err := runExternalPlugin(*pluginName, path); err != nil {
+				fmt.Fprintf(os.Stderr, "error: plugin %q exited: %v\n", *pluginName, err)
+				os.Exit(1)
+			}
+			return
+		}
 		fmt.Fprintf(os.Stderr, "error: unknown plugin %q\n", *pluginName)
 		fmt.Fprintf(os.Stderr, "available plugins: %s\n", strings.Join(availablePlugins(), ", "))
+		logPluginManifests()
 		os.Exit(1)
 	}
 
@@ -345,34 +768,21 @@ func main() {
 	fmt.Fprintf(os.Stdout, "%s\n", readyLine)
 	os.Stdout.Sync()
 
-	// Read requests from stdin, one JSON line per request.
+	// Read requests from stdin, one JSON line per request. readStdin also
+	// demultiplexes toolResultFrames into pendingTools so the tool loop
+	// inside handleRequest can block on the same stdio the requests arrive
+	// on.
 	scanner := bufio.NewScanner(os.Stdin)
 	// Increase scanner buffer for large requests (e.g. base64 images).
 	scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024)
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-
-		var req nativeRequest
-		if err := json.Unmarshal([]byte(line), &req); err != nil {
-			resp := nativeResponse{Error: fmt.Sprintf("invalid request JSON: %v", err)}
-			out, _ := json.Marshal(resp)
-			fmt.Fprintf(os.Stdout, "%s\n", out)
-			os.Stdout.Sync()
-			continue
-		}
+	requestCh := make(chan nativeRequest)
+	go readStdin(scanner, requestCh)
 
+	for req := range requestCh {
+		req := req
 		result := handleRequest(ctx, g, &req)
-		out, err := json.Marshal(result)
-		if err != nil {
-			errResp := nativeResponse{Error: fmt.Sprintf("failed to marshal response: %v", err)}
-			out, _ = json.Marshal(errResp)
-		}
-		fmt.Fprintf(os.Stdout, "%s\n", out)
-		os.Stdout.Sync()
+		writeFrame(result)
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -381,11 +791,94 @@ func main() {
 	}
 }
 
-// availablePlugins returns sorted list of registered plugin names.
+// availablePlugins returns the sorted list of plugin names, both built-in
+// and discovered on $GENKIT_PLUGINS_DIR/$PATH.
 func availablePlugins() []string {
+	seen := map[string]bool{}
 	var names []string
 	for k := range pluginRegistry {
+		seen[k] = true
 		names = append(names, k)
 	}
+	for k := range discoverExternalPlugins() {
+		if !seen[k] {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
 	return names
 }
+
+// readStdin scans stdin line by line and demultiplexes the frame kinds that
+// can appear there: nativeRequests are forwarded on reqCh; sessionControl
+// frames are applied immediately and acknowledged; toolResultFrames are
+// routed to the waiting entry in pendingTools by Ref. It closes reqCh when
+// stdin is exhausted.
+func readStdin(scanner *bufio.Scanner, reqCh chan<- nativeRequest) {
+	defer close(reqCh)
+	defer close(stdinClosed)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var peek map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(line), &peek); err != nil {
+			writeFrame(nativeResponse{Error: fmt.Sprintf("invalid request JSON: %v", err)})
+			continue
+		}
+
+		if _, ok := peek["sessionControl"]; ok {
+			var frame sessionControlFrame
+			if err := json.Unmarshal([]byte(line), &frame); err != nil {
+				writeFrame(nativeResponse{Error: fmt.Sprintf("invalid sessionControl JSON: %v", err)})
+				continue
+			}
+			switch frame.SessionControl.Action {
+			case "reset", "close":
+				resetSession(frame.SessionControl.ID)
+			default:
+				writeFrame(nativeResponse{ID: frame.ID, Error: fmt.Sprintf("unknown sessionControl action %q", frame.SessionControl.Action)})
+				continue
+			}
+			writeFrame(nativeResponse{ID: frame.ID})
+			continue
+		}
+
+		if _, ok := peek["toolResult"]; ok {
+			var frame toolResultFrame
+			if err := json.Unmarshal([]byte(line), &frame); err != nil {
+				writeFrame(nativeResponse{Error: fmt.Sprintf("invalid toolResult JSON: %v", err)})
+				continue
+			}
+			pendingToolsMu.Lock()
+			ch, ok := pendingTools[frame.ToolResult.Ref]
+			pendingToolsMu.Unlock()
+			if ok {
+				ch <- frame.ToolResult
+			}
+			continue
+		}
+
+		var req nativeRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			writeFrame(nativeResponse{Error: fmt.Sprintf("invalid request JSON: %v", err)})
+			continue
+		}
+		reqCh <- req
+	}
+}
+
+// writeFrame marshals v as a single JSON line to stdout, flushing
+// immediately so the reader on the other end of the pipe sees it without
+// waiting for buffering.
+func writeFrame(v any) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		out, _ = json.Marshal(nativeResponse{Error: fmt.Sprintf("failed to marshal response: %v", err)})
+	}
+	fmt.Fprintf(os.Stdout, "%s\n", out)
+	os.Stdout.Sync()
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func touch(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, nil, 0o755); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+}
+
+func TestAddGenkitPluginBinaries(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"genkit-plugin-foo", "genkit-plugin-bar", "not-a-plugin", "README.md"} {
+		touch(t, filepath.Join(dir, name))
+	}
+
+	found := map[string]string{}
+	addGenkitPluginBinaries(dir, found)
+
+	if len(found) != 2 {
+		t.Fatalf("found = %v, want exactly foo and bar", found)
+	}
+	if found["foo"] != filepath.Join(dir, "genkit-plugin-foo") {
+		t.Errorf("found[foo] = %q, want path inside %q", found["foo"], dir)
+	}
+	if found["bar"] != filepath.Join(dir, "genkit-plugin-bar") {
+		t.Errorf("found[bar] = %q, want path inside %q", found["bar"], dir)
+	}
+}
+
+func TestAddGenkitPluginBinariesEarlierDirWins(t *testing.T) {
+	first := filepath.Join(t.TempDir(), "first")
+	second := filepath.Join(t.TempDir(), "second")
+	for _, dir := range []string{first, second} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+		touch(t, filepath.Join(dir, "genkit-plugin-foo"))
+	}
+
+	found := map[string]string{}
+	addGenkitPluginBinaries(first, found)
+	addGenkitPluginBinaries(second, found)
+
+	if found["foo"] != filepath.Join(first, "genkit-plugin-foo") {
+		t.Errorf("found[foo] = %q, want the first directory's entry to win", found["foo"])
+	}
+}
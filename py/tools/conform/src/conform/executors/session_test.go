@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestSessionAccumulation(t *testing.T) {
+	id := "test-session-accumulation"
+	t.Cleanup(func() { resetSession(id) })
+
+	sess := sessionFor(id)
+	sess.history = append(sess.history, &ai.Message{})
+	sess.cumulativeInputTokens += 10
+	sess.cumulativeOutputTokens += 20
+
+	again := sessionFor(id)
+	again.history = append(again.history, &ai.Message{})
+	again.cumulativeInputTokens += 5
+	again.cumulativeOutputTokens += 7
+
+	if len(again.history) != 2 {
+		t.Fatalf("history length = %d, want 2 across both turns", len(again.history))
+	}
+	if again.cumulativeInputTokens != 15 || again.cumulativeOutputTokens != 27 {
+		t.Fatalf("cumulative usage = (%d, %d), want (15, 27)", again.cumulativeInputTokens, again.cumulativeOutputTokens)
+	}
+}
+
+func TestResetSessionClearsState(t *testing.T) {
+	id := "test-reset-session-clears-state"
+
+	sess := sessionFor(id)
+	sess.history = append(sess.history, &ai.Message{})
+	sess.cumulativeInputTokens = 10
+	sess.cumulativeOutputTokens = 20
+
+	resetSession(id)
+
+	fresh := sessionFor(id)
+	if len(fresh.history) != 0 || fresh.cumulativeInputTokens != 0 || fresh.cumulativeOutputTokens != 0 {
+		t.Fatalf("resetSession left state behind: %+v", fresh)
+	}
+}